@@ -0,0 +1,189 @@
+package joe
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+// BatchMemory is an optional interface a Memory implementation can provide to
+// support efficient bulk operations. If a Memory does not implement this
+// interface, Storage falls back to issuing the equivalent Set/Get/Delete
+// calls one key at a time, in parallel, bounded by SetBatchConcurrency.
+type BatchMemory interface {
+	SetMany(values map[string][]byte) error
+	GetMany(keys []string) (map[string][]byte, error)
+	DeleteMany(keys []string) (int, error)
+}
+
+// defaultBatchConcurrency is used by the BatchMemory fallback when
+// SetBatchConcurrency has not been called.
+const defaultBatchConcurrency = 8
+
+// SetBatchConcurrency sets how many Set/Get/Delete calls Storage issues in
+// parallel when the underlying Memory does not implement BatchMemory. n is
+// clamped to at least 1. The default is 8.
+func (s *Storage) SetBatchConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+
+	s.batchConcurrency = n
+}
+
+func (s *Storage) batchConcurrencyLimit() int {
+	if s.batchConcurrency < 1 {
+		return defaultBatchConcurrency
+	}
+
+	return s.batchConcurrency
+}
+
+// SetMany encodes and stores every value in values. If the underlying Memory
+// implements BatchMemory, its SetMany is used directly. Otherwise the values
+// are set one at a time via Set, in parallel, bounded by
+// SetBatchConcurrency; the first error encountered is returned and aborts
+// any Set calls that have not started yet.
+func (s *Storage) SetMany(values map[string]interface{}) error {
+	encoded := make(map[string][]byte, len(values))
+	for key, value := range values {
+		data, err := s.encoder.Encode(value)
+		if err != nil {
+			return errors.Wrapf(err, "encode data for key %q", key)
+		}
+
+		encoded[key] = data
+	}
+
+	if bm, ok := s.memory.(BatchMemory); ok {
+		return bm.SetMany(encoded)
+	}
+
+	keys := make([]string, 0, len(encoded))
+	for key := range encoded {
+		keys = append(keys, key)
+	}
+
+	return s.runBatchKeys(keys, func(key string) error {
+		return s.memory.Set(key, encoded[key])
+	})
+}
+
+// GetMany fetches every key in keys and, for each one found, decodes its
+// value into the pointer given by targets[key] (a missing or nil target
+// behaves like passing nil to Get: the value is fetched but not decoded).
+// The returned map reports which of keys were found at all, regardless of
+// whether a target was given for them. If the underlying Memory implements
+// BatchMemory, its GetMany is used to fetch the raw values in a single call,
+// otherwise Get is called for each key in parallel, bounded by
+// SetBatchConcurrency.
+func (s *Storage) GetMany(keys []string, targets map[string]interface{}) (map[string]bool, error) {
+	var raw map[string][]byte
+
+	if bm, ok := s.memory.(BatchMemory); ok {
+		var err error
+		raw, err = bm.GetMany(keys)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		raw = make(map[string][]byte, len(keys))
+		var mu sync.Mutex
+
+		err := s.runBatchKeys(keys, func(key string) error {
+			data, ok, err := s.memory.Get(key)
+			if err != nil || !ok {
+				return err
+			}
+
+			mu.Lock()
+			raw[key] = data
+			mu.Unlock()
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	found := make(map[string]bool, len(raw))
+	for key, data := range raw {
+		found[key] = true
+
+		target := targets[key]
+		if target == nil {
+			continue
+		}
+
+		if err := s.encoder.Decode(data, target); err != nil {
+			return nil, errors.Wrapf(err, "decode data for key %q", key)
+		}
+	}
+
+	return found, nil
+}
+
+// DeleteMany removes every key in keys and returns how many of them actually
+// existed. If the underlying Memory implements BatchMemory, its DeleteMany is
+// used directly, otherwise Delete is called for each key in parallel,
+// bounded by SetBatchConcurrency.
+func (s *Storage) DeleteMany(keys []string) (int, error) {
+	if bm, ok := s.memory.(BatchMemory); ok {
+		return bm.DeleteMany(keys)
+	}
+
+	var (
+		mu      sync.Mutex
+		deleted int
+	)
+
+	err := s.runBatchKeys(keys, func(key string) error {
+		ok, err := s.memory.Delete(key)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			mu.Lock()
+			deleted++
+			mu.Unlock()
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, nil
+}
+
+// runBatchKeys runs fn(key) for every key in keys, in parallel, bounded by
+// batchConcurrencyLimit. The first error returned by fn is propagated and
+// cancels any fn calls that have not started yet.
+func (s *Storage) runBatchKeys(keys []string, fn func(key string) error) error {
+	g, ctx := errgroup.WithContext(context.Background())
+	sem := make(chan struct{}, s.batchConcurrencyLimit())
+
+	for _, key := range keys {
+		key := key
+		g.Go(func() error {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return nil // a sibling already failed, nothing left to do
+			}
+			defer func() { <-sem }()
+
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return fn(key)
+		})
+	}
+
+	return g.Wait()
+}
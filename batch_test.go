@@ -0,0 +1,147 @@
+package joe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+type batchMemory struct {
+	*inMemory
+	batchUsed bool
+}
+
+func (m *batchMemory) SetMany(values map[string][]byte) error {
+	m.batchUsed = true
+	for key, value := range values {
+		if err := m.inMemory.Set(key, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *batchMemory) GetMany(keys []string) (map[string][]byte, error) {
+	m.batchUsed = true
+	result := make(map[string][]byte)
+	for _, key := range keys {
+		if value, ok, err := m.inMemory.Get(key); err != nil {
+			return nil, err
+		} else if ok {
+			result[key] = value
+		}
+	}
+
+	return result, nil
+}
+
+func (m *batchMemory) DeleteMany(keys []string) (int, error) {
+	m.batchUsed = true
+	var deleted int
+	for _, key := range keys {
+		if ok, err := m.inMemory.Delete(key); err != nil {
+			return 0, err
+		} else if ok {
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+var _ BatchMemory = &batchMemory{nil, false}
+
+func newBatchStorage(t *testing.T) (*Storage, *batchMemory) {
+	mem := &batchMemory{newInMemory(), false}
+	store := NewStorage(zaptest.NewLogger(t))
+	store.SetMemory(mem)
+	return store, mem
+}
+
+func TestStorage_BatchMemory(t *testing.T) {
+	store, mem := newBatchStorage(t)
+
+	err := store.SetMany(map[string]interface{}{"a": "1", "b": "2"})
+	require.NoError(t, err)
+	assert.True(t, mem.batchUsed)
+
+	mem.batchUsed = false
+	var a, b string
+	found, err := store.GetMany([]string{"a", "b", "does-not-exist"}, map[string]interface{}{"a": &a, "b": &b})
+	require.NoError(t, err)
+	assert.True(t, mem.batchUsed)
+	assert.Equal(t, map[string]bool{"a": true, "b": true}, found)
+	assert.Equal(t, "1", a)
+	assert.Equal(t, "2", b)
+
+	mem.batchUsed = false
+	deleted, err := store.DeleteMany([]string{"a", "b", "does-not-exist"})
+	require.NoError(t, err)
+	assert.True(t, mem.batchUsed)
+	assert.Equal(t, 2, deleted)
+}
+
+func TestStorage_BatchFallback(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	store := NewStorage(logger)
+
+	err := store.SetMany(map[string]interface{}{"a": "1", "b": "2", "c": "3"})
+	require.NoError(t, err)
+
+	var a, b, c string
+	found, err := store.GetMany([]string{"a", "b", "c", "does-not-exist"}, map[string]interface{}{"a": &a, "b": &b, "c": &c})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"a": true, "b": true, "c": true}, found)
+	assert.Equal(t, "1", a)
+	assert.Equal(t, "2", b)
+	assert.Equal(t, "3", c)
+
+	deleted, err := store.DeleteMany([]string{"a", "b", "does-not-exist"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+
+	keys, err := store.Keys()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c"}, keys)
+}
+
+func TestStorage_GetMany_ConcreteTargets(t *testing.T) {
+	// Regression test: GetMany used to decode into a bare interface{}, which
+	// is not just lossy for JSON but outright broken for encoders (such as
+	// gob) that reject decoding into an interface{} target.
+	store := NewStorage(zaptest.NewLogger(t))
+	store.SetMemoryEncoder(new(gobEncoder))
+
+	require.NoError(t, store.Set("a", "1"))
+	require.NoError(t, store.Set("b", "2"))
+
+	var a, b string
+	found, err := store.GetMany([]string{"a", "b"}, map[string]interface{}{"a": &a, "b": &b})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"a": true, "b": true}, found)
+	assert.Equal(t, "1", a)
+	assert.Equal(t, "2", b)
+}
+
+func TestStorage_GetMany_NoTarget(t *testing.T) {
+	store := NewStorage(zaptest.NewLogger(t))
+	require.NoError(t, store.Set("a", "1"))
+
+	found, err := store.GetMany([]string{"a", "does-not-exist"}, nil)
+	require.NoError(t, err, "fetching without targets should only report which keys were found")
+	assert.Equal(t, map[string]bool{"a": true}, found)
+}
+
+func TestStorage_SetBatchConcurrency(t *testing.T) {
+	store := NewStorage(zaptest.NewLogger(t))
+	assert.Equal(t, defaultBatchConcurrency, store.batchConcurrencyLimit())
+
+	store.SetBatchConcurrency(2)
+	assert.Equal(t, 2, store.batchConcurrencyLimit())
+
+	store.SetBatchConcurrency(0)
+	assert.Equal(t, 1, store.batchConcurrencyLimit())
+}
@@ -0,0 +1,15 @@
+package joe
+
+import "encoding/json"
+
+// jsonEncoder is the default MemoryEncoder used by NewStorage. It encodes
+// values as JSON.
+type jsonEncoder struct{}
+
+func (jsonEncoder) Encode(value interface{}) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+func (jsonEncoder) Decode(data []byte, target interface{}) error {
+	return json.Unmarshal(data, target)
+}
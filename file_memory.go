@@ -0,0 +1,124 @@
+package joe
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// fileKeyPrefix is prepended to every hex-encoded key before it is joined to
+// the storage directory. Without it, an empty key would hex-encode to the
+// empty string and FileMemory.path would collapse to the directory itself.
+const fileKeyPrefix = "key-"
+
+// FileMemory is a Memory implementation that persists every key as its own
+// file in a directory on disk. It lets bots survive restarts without pulling
+// in an external dependency such as Redis or BoltDB.
+type FileMemory struct {
+	mu  sync.RWMutex
+	dir string
+}
+
+// NewFileMemory creates a new FileMemory that stores its data in dir,
+// creating the directory (and any missing parents) if it does not exist yet.
+func NewFileMemory(dir string) (*FileMemory, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, errors.Wrap(err, "create storage directory")
+	}
+
+	return &FileMemory{dir: dir}, nil
+}
+
+// path returns the file path under which key is stored. Keys are hex encoded
+// so arbitrary key strings (e.g. containing slashes or dots) always map to a
+// single valid file name, and prefixed with fileKeyPrefix so the empty key
+// (which hex-encodes to the empty string) doesn't collapse to m.dir itself.
+func (m *FileMemory) path(key string) string {
+	return filepath.Join(m.dir, fileKeyPrefix+hex.EncodeToString([]byte(key)))
+}
+
+// Set implements the Memory interface.
+func (m *FileMemory) Set(key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := os.WriteFile(m.path(key), value, 0o600); err != nil {
+		return errors.Wrap(err, "write key")
+	}
+
+	return nil
+}
+
+// Get implements the Memory interface.
+func (m *FileMemory) Get(key string) ([]byte, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	value, err := os.ReadFile(m.path(key))
+	switch {
+	case os.IsNotExist(err):
+		return nil, false, nil
+	case err != nil:
+		return nil, false, errors.Wrap(err, "read key")
+	}
+
+	return value, true, nil
+}
+
+// Delete implements the Memory interface.
+func (m *FileMemory) Delete(key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	err := os.Remove(m.path(key))
+	switch {
+	case os.IsNotExist(err):
+		return false, nil
+	case err != nil:
+		return false, errors.Wrap(err, "delete key")
+	}
+
+	return true, nil
+}
+
+// Keys implements the Memory interface.
+func (m *FileMemory) Keys() ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "read storage directory")
+	}
+
+	keys := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name, ok := strings.CutPrefix(entry.Name(), fileKeyPrefix)
+		if !ok {
+			continue // not a file we created, ignore it
+		}
+
+		key, err := hex.DecodeString(name)
+		if err != nil {
+			continue // not a file we created, ignore it
+		}
+
+		keys = append(keys, string(key))
+	}
+
+	return keys, nil
+}
+
+// Close implements the Memory interface. FileMemory does not hold any open
+// file handles between calls so there is nothing to close.
+func (m *FileMemory) Close() error {
+	return nil
+}
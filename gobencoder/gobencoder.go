@@ -0,0 +1,37 @@
+// Package gobencoder provides a joe.MemoryEncoder backed by encoding/gob.
+// It is kept out of the main joe package to avoid pulling encoding/gob into
+// every consumer's binary; import it for its side effect to make it
+// available to joe.NewStorageFromConfig under the name "gob":
+//
+//	import _ "github.com/dwmunster/joe/gobencoder"
+package gobencoder
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/dwmunster/joe"
+)
+
+func init() {
+	joe.RegisterEncoder("gob", func() joe.MemoryEncoder { return new(Encoder) })
+}
+
+// Encoder is a joe.MemoryEncoder that (de-)serializes values using
+// encoding/gob.
+type Encoder struct{}
+
+// Encode implements the joe.MemoryEncoder interface.
+func (Encoder) Encode(value interface{}) ([]byte, error) {
+	data := new(bytes.Buffer)
+	if err := gob.NewEncoder(data).Encode(value); err != nil {
+		return nil, err
+	}
+
+	return data.Bytes(), nil
+}
+
+// Decode implements the joe.MemoryEncoder interface.
+func (Encoder) Decode(data []byte, target interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(target)
+}
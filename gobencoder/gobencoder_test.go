@@ -0,0 +1,40 @@
+package gobencoder_test
+
+import (
+	"testing"
+
+	"github.com/dwmunster/joe"
+	"github.com/dwmunster/joe/gobencoder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestEncoder(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	store := joe.NewStorage(logger)
+	store.SetMemoryEncoder(new(gobencoder.Encoder))
+
+	val := []string{"foo", "bar"}
+	require.NoError(t, store.Set("test", val))
+
+	var actual []string
+	ok, err := store.Get("test", &actual)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, val, actual)
+}
+
+func TestEncoder_RegistersByImport(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	store, err := joe.NewStorageFromConfig(logger, joe.StorageConfig{Encoder: "gob"})
+	require.NoError(t, err)
+
+	var actual string
+	require.NoError(t, store.Set("test", "foo"))
+
+	ok, err := store.Get("test", &actual)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "foo", actual)
+}
@@ -0,0 +1,55 @@
+package joe
+
+import "sync"
+
+// inMemory is the default Memory implementation used by NewStorage. It keeps
+// all data in a map and does not persist anything across process restarts.
+type inMemory struct {
+	mu   sync.RWMutex
+	data map[string][]byte
+}
+
+func newInMemory() *inMemory {
+	return &inMemory{data: map[string][]byte{}}
+}
+
+func (m *inMemory) Set(key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.data[key] = value
+	return nil
+}
+
+func (m *inMemory) Get(key string) ([]byte, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	value, ok := m.data[key]
+	return value, ok, nil
+}
+
+func (m *inMemory) Delete(key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.data[key]
+	delete(m.data, key)
+	return ok, nil
+}
+
+func (m *inMemory) Keys() ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := make([]string, 0, len(m.data))
+	for k := range m.data {
+		keys = append(keys, k)
+	}
+
+	return keys, nil
+}
+
+func (m *inMemory) Close() error {
+	return nil
+}
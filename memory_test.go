@@ -0,0 +1,48 @@
+package joe
+
+import (
+	"testing"
+
+	"github.com/dwmunster/joe/memorytest"
+)
+
+func TestInMemory(t *testing.T) {
+	memorytest.Suite(t, func() memorytest.Memory {
+		return newInMemory()
+	})
+}
+
+func TestPrefixAwareMemory(t *testing.T) {
+	memorytest.Suite(t, func() memorytest.Memory {
+		return &prefixAwareMemory{newInMemory(), false}
+	})
+}
+
+func TestFileMemory(t *testing.T) {
+	memorytest.Suite(t, func() memorytest.Memory {
+		mem, err := NewFileMemory(t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return mem
+	})
+}
+
+func TestBatchMemory(t *testing.T) {
+	memorytest.Suite(t, func() memorytest.Memory {
+		return &batchMemory{newInMemory(), false}
+	})
+}
+
+func TestStreamingMemory(t *testing.T) {
+	memorytest.Suite(t, func() memorytest.Memory {
+		return &streamingMemory{newInMemory(), false}
+	})
+}
+
+func TestRangeMemory(t *testing.T) {
+	memorytest.Suite(t, func() memorytest.Memory {
+		return &rangeMemory{newInMemory(), false, false}
+	})
+}
@@ -0,0 +1,260 @@
+// Package memorytest provides a shared conformance test suite for joe.Memory
+// implementations, modeled after go-git's storage/test package. Third-party
+// backends can call Suite from their own tests to prove they behave the way
+// joe.Storage expects, without having to duplicate joe's own test bodies.
+//
+// The Memory and PrefixAwareMemory interfaces declared here intentionally
+// mirror joe.Memory and joe.PrefixAwareMemory method-for-method instead of
+// embedding them, so this package does not need to import joe at all; any
+// joe.Memory implementation already satisfies them.
+package memorytest
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Memory mirrors joe.Memory.
+type Memory interface {
+	Set(key string, value []byte) error
+	Get(key string) ([]byte, bool, error)
+	Delete(key string) (bool, error)
+	Keys() ([]string, error)
+	Close() error
+}
+
+// PrefixAwareMemory mirrors joe.PrefixAwareMemory.
+type PrefixAwareMemory interface {
+	KeysWithPrefix(prefix string) ([]string, error)
+}
+
+// BatchMemory mirrors joe.BatchMemory.
+type BatchMemory interface {
+	SetMany(values map[string][]byte) error
+	GetMany(keys []string) (map[string][]byte, error)
+	DeleteMany(keys []string) (int, error)
+}
+
+// StreamingMemory mirrors joe.StreamingMemory.
+type StreamingMemory interface {
+	SetStream(key string, r io.Reader) error
+	GetStream(key string) (io.ReadCloser, bool, error)
+}
+
+// RangeMemory mirrors joe.RangeMemory.
+type RangeMemory interface {
+	KeysInRange(start, end string) ([]string, error)
+}
+
+// PrefixDeleter mirrors joe.PrefixDeleter.
+type PrefixDeleter interface {
+	DeleteWithPrefix(prefix string) (int, error)
+}
+
+// Suite runs the full Memory conformance suite against the Memory returned
+// by factory. factory is called once per sub-test so implementations do not
+// need to support being reset between runs. Optional interfaces such as
+// PrefixAwareMemory are only exercised if the Memory returned by factory
+// implements them; otherwise the corresponding sub-test is skipped.
+func Suite(t *testing.T, factory func() Memory) {
+	t.Run("SetGetDelete", func(t *testing.T) { testSetGetDelete(t, factory()) })
+	t.Run("EmptyKey", func(t *testing.T) { testEmptyKey(t, factory()) })
+	t.Run("Keys", func(t *testing.T) { testKeys(t, factory()) })
+	t.Run("Close", func(t *testing.T) { testClose(t, factory()) })
+	t.Run("PrefixAwareMemory", func(t *testing.T) { testPrefixAwareMemory(t, factory()) })
+	t.Run("BatchMemory", func(t *testing.T) { testBatchMemory(t, factory()) })
+	t.Run("StreamingMemory", func(t *testing.T) { testStreamingMemory(t, factory()) })
+	t.Run("RangeMemory", func(t *testing.T) { testRangeMemory(t, factory()) })
+	t.Run("PrefixDeleter", func(t *testing.T) { testPrefixDeleter(t, factory()) })
+}
+
+func testSetGetDelete(t *testing.T, mem Memory) {
+	t.Helper()
+	defer mem.Close()
+
+	_, ok, err := mem.Get("test")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, mem.Set("test", []byte("foo")))
+	require.NoError(t, mem.Set("test", []byte("foo")), "setting a key more than once should not error")
+
+	value, ok, err := mem.Get("test")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("foo"), value)
+
+	ok, err = mem.Delete("does-not-exist")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = mem.Delete("test")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	_, ok, err = mem.Get("test")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+// testEmptyKey makes sure the empty string is treated like any other legal
+// key. A naive on-disk encoding can make the empty key collide with a
+// backend's own storage location (e.g. a directory), so every Memory must be
+// able to round-trip it.
+func testEmptyKey(t *testing.T, mem Memory) {
+	t.Helper()
+	defer mem.Close()
+
+	_, ok, err := mem.Get("")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, mem.Set("", []byte("empty-key")))
+
+	value, ok, err := mem.Get("")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("empty-key"), value)
+
+	ok, err = mem.Delete("")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func testKeys(t *testing.T, mem Memory) {
+	t.Helper()
+	defer mem.Close()
+
+	require.NoError(t, mem.Set("test", []byte("foo")))
+	require.NoError(t, mem.Set("test-2", []byte("bar")))
+
+	keys, err := mem.Keys()
+	require.NoError(t, err)
+
+	sort.Strings(keys)
+	assert.Equal(t, []string{"test", "test-2"}, keys)
+}
+
+func testClose(t *testing.T, mem Memory) {
+	t.Helper()
+	assert.NoError(t, mem.Close())
+}
+
+func testPrefixAwareMemory(t *testing.T, mem Memory) {
+	t.Helper()
+
+	pam, ok := mem.(PrefixAwareMemory)
+	if !ok {
+		t.Skip("Memory does not implement memorytest.PrefixAwareMemory")
+	}
+	defer mem.Close()
+
+	entries := []string{"test.k3", "test.k1", "non-matching", "test.k2"}
+	for _, k := range entries {
+		require.NoError(t, mem.Set(k, nil))
+	}
+
+	keys, err := pam.KeysWithPrefix("test.")
+	require.NoError(t, err)
+
+	sort.Strings(keys)
+	assert.Equal(t, []string{"test.k1", "test.k2", "test.k3"}, keys)
+}
+
+func testBatchMemory(t *testing.T, mem Memory) {
+	t.Helper()
+
+	bm, ok := mem.(BatchMemory)
+	if !ok {
+		t.Skip("Memory does not implement memorytest.BatchMemory")
+	}
+	defer mem.Close()
+
+	require.NoError(t, bm.SetMany(map[string][]byte{"a": []byte("1"), "b": []byte("2")}))
+
+	values, err := bm.GetMany([]string{"a", "b", "does-not-exist"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"a": []byte("1"), "b": []byte("2")}, values)
+
+	deleted, err := bm.DeleteMany([]string{"a", "b", "does-not-exist"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+
+	values, err = bm.GetMany([]string{"a", "b"})
+	require.NoError(t, err)
+	assert.Empty(t, values)
+}
+
+func testStreamingMemory(t *testing.T, mem Memory) {
+	t.Helper()
+
+	sm, ok := mem.(StreamingMemory)
+	if !ok {
+		t.Skip("Memory does not implement memorytest.StreamingMemory")
+	}
+	defer mem.Close()
+
+	_, ok, err := sm.GetStream("test")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, sm.SetStream("test", strings.NewReader("foo")))
+
+	r, ok, err := sm.GetStream("test")
+	require.NoError(t, err)
+	require.True(t, ok)
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("foo"), data)
+}
+
+func testRangeMemory(t *testing.T, mem Memory) {
+	t.Helper()
+
+	rm, ok := mem.(RangeMemory)
+	if !ok {
+		t.Skip("Memory does not implement memorytest.RangeMemory")
+	}
+	defer mem.Close()
+
+	entries := []string{"metrics.2024-01-01", "metrics.2024-01-15", "metrics.2024-02-01", "other"}
+	for _, k := range entries {
+		require.NoError(t, mem.Set(k, nil))
+	}
+
+	keys, err := rm.KeysInRange("metrics.2024-01-01", "metrics.2024-02-01")
+	require.NoError(t, err)
+
+	sort.Strings(keys)
+	assert.Equal(t, []string{"metrics.2024-01-01", "metrics.2024-01-15"}, keys)
+}
+
+func testPrefixDeleter(t *testing.T, mem Memory) {
+	t.Helper()
+
+	pd, ok := mem.(PrefixDeleter)
+	if !ok {
+		t.Skip("Memory does not implement memorytest.PrefixDeleter")
+	}
+	defer mem.Close()
+
+	entries := []string{"test.k1", "test.k2", "other"}
+	for _, k := range entries {
+		require.NoError(t, mem.Set(k, nil))
+	}
+
+	deleted, err := pd.DeleteWithPrefix("test.")
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+
+	keys, err := mem.Keys()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"other"}, keys)
+}
@@ -0,0 +1,79 @@
+package joe
+
+import "sort"
+
+// RangeMemory is an optional interface a Memory implementation can satisfy
+// to answer Storage.KeysInRange more efficiently than scanning all keys
+// returned by Keys.
+type RangeMemory interface {
+	// KeysInRange returns all keys k for which start <= k < end, i.e. a
+	// lexicographic half-open range.
+	KeysInRange(start, end string) ([]string, error)
+}
+
+// PrefixDeleter is an optional interface a Memory implementation can satisfy
+// to delete all keys sharing a prefix more efficiently than Storage deleting
+// them one by one.
+type PrefixDeleter interface {
+	DeleteWithPrefix(prefix string) (int, error)
+}
+
+// KeysInRange returns a sorted list of all keys k for which start <= k < end
+// (a lexicographic half-open range), which is useful for time-bucketed keys
+// such as "metrics.2024-01-01" .. "metrics.2024-02-01". If the configured
+// Memory implements RangeMemory its optimized lookup is used, otherwise the
+// result of Keys is filtered manually.
+func (s *Storage) KeysInRange(start, end string) ([]string, error) {
+	if m, ok := s.memory.(RangeMemory); ok {
+		keys, err := m.KeysInRange(start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		sort.Strings(keys)
+		return keys, nil
+	}
+
+	keys, err := s.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []string
+	for _, k := range keys {
+		if k >= start && k < end {
+			results = append(results, k)
+		}
+	}
+
+	return results, nil
+}
+
+// DeleteWithPrefix removes every key starting with prefix and returns how
+// many keys were deleted. If the configured Memory implements PrefixDeleter
+// its optimized deletion is used, otherwise matching keys (found via
+// KeysWithPrefix) are deleted one by one.
+func (s *Storage) DeleteWithPrefix(prefix string) (int, error) {
+	if pd, ok := s.memory.(PrefixDeleter); ok {
+		return pd.DeleteWithPrefix(prefix)
+	}
+
+	keys, err := s.KeysWithPrefix(prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	var deleted int
+	for _, k := range keys {
+		ok, err := s.memory.Delete(k)
+		if err != nil {
+			return deleted, err
+		}
+
+		if ok {
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
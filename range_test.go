@@ -0,0 +1,133 @@
+package joe
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// rangeMemory is a Memory test double, in the same style as
+// prefixAwareMemory, that flips a boolean whenever its fast paths are used
+// so tests can assert the optimization actually fires.
+type rangeMemory struct {
+	*inMemory
+	rangeUsed  bool
+	deleteUsed bool
+}
+
+func (m *rangeMemory) KeysInRange(start, end string) ([]string, error) {
+	m.rangeUsed = true
+
+	keys, err := m.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []string
+	for _, k := range keys {
+		if k >= start && k < end {
+			results = append(results, k)
+		}
+	}
+
+	sort.Strings(results)
+	return results, nil
+}
+
+func (m *rangeMemory) DeleteWithPrefix(prefix string) (int, error) {
+	m.deleteUsed = true
+
+	keys, err := m.Keys()
+	if err != nil {
+		return 0, err
+	}
+
+	var deleted int
+	for _, k := range keys {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+
+		if ok, err := m.Delete(k); err != nil {
+			return deleted, err
+		} else if ok {
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+var (
+	_ RangeMemory   = &rangeMemory{nil, false, false}
+	_ PrefixDeleter = &rangeMemory{nil, false, false}
+)
+
+func newRangeStorage(t *testing.T) (*Storage, *rangeMemory) {
+	mem := &rangeMemory{newInMemory(), false, false}
+	store := NewStorage(zaptest.NewLogger(t))
+	store.SetMemory(mem)
+	return store, mem
+}
+
+func TestStorage_KeysInRange(t *testing.T) {
+	store, mem := newRangeStorage(t)
+
+	for _, k := range []string{"metrics.2024-01-01", "metrics.2024-01-15", "metrics.2024-02-01", "other"} {
+		require.NoError(t, store.Set(k, nil))
+	}
+
+	keys, err := store.KeysInRange("metrics.2024-01-01", "metrics.2024-02-01")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"metrics.2024-01-01", "metrics.2024-01-15"}, keys)
+	assert.True(t, mem.rangeUsed)
+}
+
+func TestStorage_KeysInRange_Fallback(t *testing.T) {
+	store := NewStorage(zaptest.NewLogger(t))
+
+	for _, k := range []string{"a", "b", "c", "d"} {
+		require.NoError(t, store.Set(k, nil))
+	}
+
+	keys, err := store.KeysInRange("b", "d")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "c"}, keys)
+}
+
+func TestStorage_DeleteWithPrefix(t *testing.T) {
+	store, mem := newRangeStorage(t)
+
+	for _, k := range []string{"test.k1", "test.k2", "other"} {
+		require.NoError(t, store.Set(k, nil))
+	}
+
+	deleted, err := store.DeleteWithPrefix("test.")
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	assert.True(t, mem.deleteUsed)
+
+	keys, err := store.Keys()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"other"}, keys)
+}
+
+func TestStorage_DeleteWithPrefix_Fallback(t *testing.T) {
+	store := NewStorage(zaptest.NewLogger(t))
+
+	for _, k := range []string{"test.k1", "test.k2", "other"} {
+		require.NoError(t, store.Set(k, nil))
+	}
+
+	deleted, err := store.DeleteWithPrefix("test.")
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+
+	keys, err := store.Keys()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"other"}, keys)
+}
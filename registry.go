@@ -0,0 +1,118 @@
+package joe
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// EncoderFactory creates a new MemoryEncoder. Factories are registered under
+// a name via RegisterEncoder so they can be selected by NewStorageFromConfig.
+type EncoderFactory func() MemoryEncoder
+
+// MemoryFactory creates a new Memory from the given configuration options.
+// Factories are registered under a name via RegisterMemory so they can be
+// selected by NewStorageFromConfig.
+type MemoryFactory func(options map[string]interface{}) (Memory, error)
+
+var (
+	registryMu       sync.RWMutex
+	encoderFactories = map[string]EncoderFactory{}
+	memoryFactories  = map[string]MemoryFactory{}
+)
+
+func init() {
+	RegisterEncoder("json", func() MemoryEncoder { return new(jsonEncoder) })
+	RegisterMemory("memory", func(map[string]interface{}) (Memory, error) {
+		return newInMemory(), nil
+	})
+	RegisterMemory("file", func(options map[string]interface{}) (Memory, error) {
+		dir, _ := options["dir"].(string)
+		if dir == "" {
+			return nil, errors.New(`file memory requires a non-empty "dir" option`)
+		}
+
+		return NewFileMemory(dir)
+	})
+}
+
+// RegisterEncoder registers a MemoryEncoder factory under the given name,
+// making it available to NewStorageFromConfig. It is meant to be called from
+// init() functions, e.g. by third-party packages that ship their own
+// MemoryEncoder. Registering under a name that is already taken overwrites
+// the previous registration.
+func RegisterEncoder(name string, factory EncoderFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	encoderFactories[name] = factory
+}
+
+// RegisterMemory registers a Memory factory under the given name, making it
+// available to NewStorageFromConfig. It is meant to be called from init()
+// functions, e.g. by third-party packages that ship their own Memory
+// backend. Registering under a name that is already taken overwrites the
+// previous registration.
+func RegisterMemory(name string, factory MemoryFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	memoryFactories[name] = factory
+}
+
+// StorageConfig configures the Memory and MemoryEncoder that
+// NewStorageFromConfig wires up.
+type StorageConfig struct {
+	// Memory is the name a Memory backend was registered under via
+	// RegisterMemory. Defaults to "memory" (the built-in in-memory backend).
+	Memory string
+
+	// Encoder is the name a MemoryEncoder was registered under via
+	// RegisterEncoder. Defaults to "json".
+	Encoder string
+
+	// Options are passed verbatim to the Memory factory, e.g. connection
+	// details or a file path. The built-in "file" backend reads its storage
+	// directory from Options["dir"].
+	Options map[string]interface{}
+}
+
+// NewStorageFromConfig builds a *Storage from the Memory and MemoryEncoder
+// registered under the names given in cfg. This lets bot authors switch
+// persistence backends via configuration rather than recompiling main, as
+// long as the desired backend has registered itself (typically via an
+// init() function in the package that implements it).
+func NewStorageFromConfig(logger *zap.Logger, cfg StorageConfig) (*Storage, error) {
+	memName := cfg.Memory
+	if memName == "" {
+		memName = "memory"
+	}
+
+	encName := cfg.Encoder
+	if encName == "" {
+		encName = "json"
+	}
+
+	registryMu.RLock()
+	memFactory, ok := memoryFactories[memName]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, errors.Errorf("no Memory registered under name %q", memName)
+	}
+
+	registryMu.RLock()
+	encFactory, ok := encoderFactories[encName]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, errors.Errorf("no MemoryEncoder registered under name %q", encName)
+	}
+
+	mem, err := memFactory(cfg.Options)
+	if err != nil {
+		return nil, errors.Wrapf(err, "create memory %q", memName)
+	}
+
+	store := NewStorage(logger)
+	store.SetMemory(mem)
+	store.SetMemoryEncoder(encFactory())
+	return store, nil
+}
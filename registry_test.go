@@ -0,0 +1,58 @@
+package joe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestNewStorageFromConfig_Defaults(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	store, err := NewStorageFromConfig(logger, StorageConfig{})
+	require.NoError(t, err)
+
+	assert.IsType(t, new(jsonEncoder), store.encoder)
+	assert.IsType(t, newInMemory(), store.memory)
+}
+
+func TestNewStorageFromConfig_UnknownBackend(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	_, err := NewStorageFromConfig(logger, StorageConfig{Memory: "does-not-exist"})
+	assert.EqualError(t, err, `no Memory registered under name "does-not-exist"`)
+
+	_, err = NewStorageFromConfig(logger, StorageConfig{Encoder: "does-not-exist"})
+	assert.EqualError(t, err, `no MemoryEncoder registered under name "does-not-exist"`)
+}
+
+func TestNewStorageFromConfig_File(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	_, err := NewStorageFromConfig(logger, StorageConfig{Memory: "file"})
+	assert.EqualError(t, err, `create memory "file": file memory requires a non-empty "dir" option`)
+
+	store, err := NewStorageFromConfig(logger, StorageConfig{
+		Memory:  "file",
+		Options: map[string]interface{}{"dir": t.TempDir()},
+	})
+	require.NoError(t, err)
+
+	_, ok := store.memory.(*FileMemory)
+	assert.True(t, ok)
+}
+
+func TestRegisterMemory(t *testing.T) {
+	RegisterMemory("test-registry-memory", func(options map[string]interface{}) (Memory, error) {
+		return &prefixAwareMemory{newInMemory(), false}, nil
+	})
+
+	logger := zaptest.NewLogger(t)
+	store, err := NewStorageFromConfig(logger, StorageConfig{Memory: "test-registry-memory"})
+	require.NoError(t, err)
+
+	_, ok := store.memory.(*prefixAwareMemory)
+	assert.True(t, ok)
+}
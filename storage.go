@@ -0,0 +1,164 @@
+// Package joe contains the code of the Joe Bot library.
+package joe
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// Storage provides a simple key-value store that Joe bots can use to persist
+// data across process restarts (e.g. in the Bot.Brain). The actual data is
+// kept by a Memory implementation and (de-)serialized using a MemoryEncoder.
+type Storage struct {
+	logger  *zap.Logger
+	memory  Memory
+	encoder MemoryEncoder
+
+	// batchConcurrency is read by the BatchMemory fallback in batch.go. Zero
+	// means "use defaultBatchConcurrency".
+	batchConcurrency int
+}
+
+// Memory is the underlying key-value store used by Storage. Brain
+// implementations can swap in their own Memory (e.g. backed by Redis or
+// BoltDB) via Storage.SetMemory to persist data outside of the bot process.
+type Memory interface {
+	Set(key string, value []byte) error
+	Get(key string) ([]byte, bool, error)
+	Delete(key string) (bool, error)
+	Keys() ([]string, error)
+	Close() error
+}
+
+// PrefixAwareMemory is an optional interface a Memory implementation can
+// satisfy to answer Storage.KeysWithPrefix more efficiently than scanning all
+// keys returned by Keys.
+type PrefixAwareMemory interface {
+	KeysWithPrefix(prefix string) ([]string, error)
+}
+
+// MemoryEncoder is used by Storage to encode and decode the values passed to
+// Storage.Set and Storage.Get. The default encoder marshals values as JSON.
+type MemoryEncoder interface {
+	Encode(value interface{}) ([]byte, error)
+	Decode(data []byte, target interface{}) error
+}
+
+// NewStorage creates a new *Storage that keeps its data in memory using a
+// simple map. Use Storage.SetMemory to configure a different backend.
+func NewStorage(logger *zap.Logger) *Storage {
+	return &Storage{
+		logger:  logger,
+		memory:  newInMemory(),
+		encoder: new(jsonEncoder),
+	}
+}
+
+// SetMemory changes the Memory implementation that actually stores the data.
+func (s *Storage) SetMemory(m Memory) {
+	s.memory = m
+}
+
+// SetMemoryEncoder changes the MemoryEncoder used to (de-)serialize values.
+func (s *Storage) SetMemoryEncoder(enc MemoryEncoder) {
+	s.encoder = enc
+}
+
+// Set encodes value and stores it under the given key, overwriting any value
+// that was previously stored under the same key. If the configured Memory
+// and MemoryEncoder both support streaming (see StreamingMemory and
+// StreamEncoder), value is streamed to the backend without ever holding the
+// fully encoded value in memory.
+func (s *Storage) Set(key string, value interface{}) error {
+	if sm, se, ok := s.streaming(); ok {
+		return s.setStream(sm, se, key, value)
+	}
+
+	data, err := s.encoder.Encode(value)
+	if err != nil {
+		return errors.Wrap(err, "encode data")
+	}
+
+	return s.memory.Set(key, data)
+}
+
+// Get decodes the value stored under the given key into target. If target is
+// nil the value is not decoded and Get only reports if the key was found.
+// The returned bool indicates if the key was found at all. If the configured
+// Memory and MemoryEncoder both support streaming (see StreamingMemory and
+// StreamEncoder), the value is streamed from the backend without ever
+// holding the fully encoded value in memory.
+func (s *Storage) Get(key string, target interface{}) (bool, error) {
+	if sm, se, ok := s.streaming(); ok {
+		return s.getStream(sm, se, key, target)
+	}
+
+	data, ok, err := s.memory.Get(key)
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	if target == nil {
+		return true, nil
+	}
+
+	if err := s.encoder.Decode(data, target); err != nil {
+		return false, errors.Wrap(err, "decode data")
+	}
+
+	return true, nil
+}
+
+// Delete removes the value stored under the given key, if any. The returned
+// bool indicates if the key existed.
+func (s *Storage) Delete(key string) (bool, error) {
+	return s.memory.Delete(key)
+}
+
+// Keys returns a sorted list of all keys currently stored.
+func (s *Storage) Keys() ([]string, error) {
+	keys, err := s.memory.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// KeysWithPrefix returns a sorted list of all keys starting with prefix. If
+// the configured Memory implements PrefixAwareMemory its optimized lookup is
+// used, otherwise the result of Keys is filtered manually.
+func (s *Storage) KeysWithPrefix(prefix string) ([]string, error) {
+	if m, ok := s.memory.(PrefixAwareMemory); ok {
+		keys, err := m.KeysWithPrefix(prefix)
+		if err != nil {
+			return nil, err
+		}
+
+		sort.Strings(keys)
+		return keys, nil
+	}
+
+	keys, err := s.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []string
+	for _, k := range keys {
+		if strings.HasPrefix(k, prefix) {
+			results = append(results, k)
+		}
+	}
+
+	return results, nil
+}
+
+// Close closes the underlying Memory.
+func (s *Storage) Close() error {
+	return s.memory.Close()
+}
@@ -98,8 +98,12 @@ func TestStorage_EncoderErrors(t *testing.T) {
 	assert.False(t, ok)
 }
 
-// gobEncoder is an example of a different encoder. This is not part of joe to
-// avoid the extra import in production code.
+// gobEncoder is a fault-injecting MemoryEncoder double used to exercise
+// Storage's encode/decode error-wrapping paths (see encodeErr/decodeErr
+// below). It is intentionally kept here rather than reusing the production
+// gobencoder.Encoder from the gobencoder subpackage: gobencoder imports joe
+// to register itself, so importing it back from joe's own internal tests
+// would create an import cycle.
 type gobEncoder struct {
 	encodeErr error
 	decodeErr error
@@ -0,0 +1,82 @@
+package joe
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// StreamEncoder is an optional interface a MemoryEncoder can implement to
+// (de-)serialize values directly against an io.Writer/io.Reader, instead of
+// returning the fully encoded value as a []byte. Storage only uses it when
+// the configured Memory also implements StreamingMemory, so implementing
+// StreamEncoder never changes the MemoryEncoder contract existing backends
+// rely on.
+type StreamEncoder interface {
+	EncodeTo(w io.Writer, value interface{}) error
+	DecodeFrom(r io.Reader, target interface{}) error
+}
+
+// StreamingMemory is an optional interface a Memory implementation can
+// provide to stream large values to and from its backing store instead of
+// requiring the full value as a []byte. Storage only uses it when the
+// configured MemoryEncoder also implements StreamEncoder.
+type StreamingMemory interface {
+	SetStream(key string, r io.Reader) error
+	GetStream(key string) (io.ReadCloser, bool, error)
+}
+
+// streaming returns the configured memory and encoder cast to their
+// streaming variants, and whether both are available.
+func (s *Storage) streaming() (StreamingMemory, StreamEncoder, bool) {
+	sm, ok := s.memory.(StreamingMemory)
+	if !ok {
+		return nil, nil, false
+	}
+
+	se, ok := s.encoder.(StreamEncoder)
+	if !ok {
+		return nil, nil, false
+	}
+
+	return sm, se, true
+}
+
+func (s *Storage) setStream(sm StreamingMemory, se StreamEncoder, key string, value interface{}) error {
+	pr, pw := io.Pipe()
+
+	go func() {
+		pw.CloseWithError(se.EncodeTo(pw, value))
+	}()
+
+	err := sm.SetStream(key, pr)
+
+	// If SetStream returns before draining pr to EOF (e.g. it aborts early
+	// on a size limit), the encoder goroutine's pending pw.Write would block
+	// forever. Closing pr unblocks it by making that Write fail instead.
+	pr.CloseWithError(err)
+
+	if err != nil {
+		return errors.Wrap(err, "encode data")
+	}
+
+	return nil
+}
+
+func (s *Storage) getStream(sm StreamingMemory, se StreamEncoder, key string, target interface{}) (bool, error) {
+	r, ok, err := sm.GetStream(key)
+	if err != nil || !ok {
+		return ok, err
+	}
+	defer r.Close()
+
+	if target == nil {
+		return true, nil
+	}
+
+	if err := se.DecodeFrom(r, target); err != nil {
+		return false, errors.Wrap(err, "decode data")
+	}
+
+	return true, nil
+}
@@ -0,0 +1,79 @@
+package joe
+
+import (
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// BenchmarkStorage_Set compares the buffered and streaming Set paths on a
+// multi-MB value, to justify the extra surface StreamEncoder/StreamingMemory
+// add on top of the plain MemoryEncoder/Memory contract.
+func BenchmarkStorage_Set(b *testing.B) {
+	value := make([]byte, 8<<20) // 8 MiB
+
+	b.Run("Buffered", func(b *testing.B) {
+		store := NewStorage(zaptest.NewLogger(b))
+		store.SetMemoryEncoder(streamJSONEncoder{})
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := store.Set("bench", value); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Streaming", func(b *testing.B) {
+		store := NewStorage(zaptest.NewLogger(b))
+		store.SetMemory(&streamingMemory{newInMemory(), false})
+		store.SetMemoryEncoder(streamJSONEncoder{})
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := store.Set("bench", value); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkStorage_Get compares the buffered and streaming Get paths on a
+// multi-MB value, to justify the extra surface StreamEncoder/StreamingMemory
+// add on top of the plain MemoryEncoder/Memory contract.
+func BenchmarkStorage_Get(b *testing.B) {
+	value := make([]byte, 8<<20) // 8 MiB
+
+	b.Run("Buffered", func(b *testing.B) {
+		store := NewStorage(zaptest.NewLogger(b))
+		store.SetMemoryEncoder(streamJSONEncoder{})
+		if err := store.Set("bench", value); err != nil {
+			b.Fatal(err)
+		}
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			var actual []byte
+			if _, err := store.Get("bench", &actual); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Streaming", func(b *testing.B) {
+		store := NewStorage(zaptest.NewLogger(b))
+		store.SetMemory(&streamingMemory{newInMemory(), false})
+		store.SetMemoryEncoder(streamJSONEncoder{})
+		if err := store.Set("bench", value); err != nil {
+			b.Fatal(err)
+		}
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			var actual []byte
+			if _, err := store.Get("bench", &actual); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package joe
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// streamJSONEncoder is a MemoryEncoder that also implements StreamEncoder by
+// delegating to the standard library's streaming JSON encoder/decoder.
+type streamJSONEncoder struct{ jsonEncoder }
+
+func (streamJSONEncoder) EncodeTo(w io.Writer, value interface{}) error {
+	return json.NewEncoder(w).Encode(value)
+}
+
+func (streamJSONEncoder) DecodeFrom(r io.Reader, target interface{}) error {
+	return json.NewDecoder(r).Decode(target)
+}
+
+var _ StreamEncoder = streamJSONEncoder{}
+
+type streamingMemory struct {
+	*inMemory
+	streamUsed bool
+}
+
+func (m *streamingMemory) SetStream(key string, r io.Reader) error {
+	m.streamUsed = true
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	return m.inMemory.Set(key, data)
+}
+
+func (m *streamingMemory) GetStream(key string) (io.ReadCloser, bool, error) {
+	m.streamUsed = true
+
+	data, ok, err := m.inMemory.Get(key)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+
+	return io.NopCloser(bytes.NewReader(data)), true, nil
+}
+
+var _ StreamingMemory = &streamingMemory{nil, false}
+
+func TestStorage_Streaming(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	mem := &streamingMemory{newInMemory(), false}
+
+	store := NewStorage(logger)
+	store.SetMemory(mem)
+	store.SetMemoryEncoder(streamJSONEncoder{})
+
+	require.NoError(t, store.Set("test", []string{"foo", "bar"}))
+	assert.True(t, mem.streamUsed, "Set should have used the streaming path")
+
+	mem.streamUsed = false
+	var actual []string
+	ok, err := store.Get("test", &actual)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, mem.streamUsed, "Get should have used the streaming path")
+	assert.Equal(t, []string{"foo", "bar"}, actual)
+}
+
+// abortingStreamingMemory is a StreamingMemory that gives up on SetStream
+// after reading a single byte, simulating a size-capped backend that aborts
+// before draining r to EOF.
+type abortingStreamingMemory struct {
+	*inMemory
+}
+
+func (m *abortingStreamingMemory) SetStream(key string, r io.Reader) error {
+	buf := make([]byte, 1)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return err
+	}
+
+	return errors.New("value too large")
+}
+
+func (m *abortingStreamingMemory) GetStream(key string) (io.ReadCloser, bool, error) {
+	data, ok, err := m.inMemory.Get(key)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+
+	return io.NopCloser(bytes.NewReader(data)), true, nil
+}
+
+var _ StreamingMemory = &abortingStreamingMemory{}
+
+func TestStorage_SetStream_NoGoroutineLeakOnEarlyReturn(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	store := NewStorage(logger)
+	store.SetMemory(&abortingStreamingMemory{newInMemory()})
+	store.SetMemoryEncoder(streamJSONEncoder{})
+
+	before := runtime.NumGoroutine()
+
+	value := make([]byte, 10<<20) // 10 MiB, far larger than the 1 byte read
+	err := store.Set("test", value)
+	assert.EqualError(t, err, "encode data: value too large")
+
+	var after int
+	for i := 0; i < 100; i++ {
+		after = runtime.NumGoroutine()
+		if after <= before {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("setStream leaked its encoder goroutine: had %d goroutines before, %d after", before, after)
+}
+
+func TestStorage_StreamingRequiresBothSides(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	// The encoder supports streaming but the default in-memory backend does
+	// not, so Storage should quietly fall back to the buffered path.
+	store := NewStorage(logger)
+	store.SetMemoryEncoder(streamJSONEncoder{})
+
+	require.NoError(t, store.Set("test", "foo"))
+
+	var actual string
+	ok, err := store.Get("test", &actual)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "foo", actual)
+}